@@ -0,0 +1,46 @@
+package tsdb
+
+import "testing"
+
+func TestBufferedSeriesIteratorPeekBack(t *testing.T) {
+	b := NewBuffer(newSampleSeriesIterator(
+		sample{0, 0}, sample{10, 1}, sample{20, 2}, sample{30, 3},
+	), 15)
+
+	for b.Next() {
+	}
+	if err := b.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// delta=15 keeps samples back to t-15, so after draining the iterator
+	// at t=30 only the samples at 20 and 30 should remain buffered.
+	if ts, v, ok := b.PeekBack(0); !ok || ts != 30 || v != 3 {
+		t.Errorf("PeekBack(0) = (%d, %v, %v), want (30, 3, true)", ts, v, ok)
+	}
+	if ts, v, ok := b.PeekBack(1); !ok || ts != 20 || v != 2 {
+		t.Errorf("PeekBack(1) = (%d, %v, %v), want (20, 2, true)", ts, v, ok)
+	}
+	if _, _, ok := b.PeekBack(2); ok {
+		t.Errorf("PeekBack(2) = ok, want false (sample at t=10 evicted)")
+	}
+}
+
+func TestBufferedSeriesIteratorPeekBackAt(t *testing.T) {
+	b := NewBuffer(newSampleSeriesIterator(
+		sample{0, 0}, sample{10, 1}, sample{20, 2}, sample{30, 3},
+	), 100)
+
+	for b.Next() {
+	}
+	if err := b.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ts, v, ok := b.PeekBackAt(25); !ok || ts != 20 || v != 2 {
+		t.Errorf("PeekBackAt(25) = (%d, %v, %v), want (20, 2, true)", ts, v, ok)
+	}
+	if _, _, ok := b.PeekBackAt(-1); ok {
+		t.Errorf("PeekBackAt(-1) = ok, want false (no sample before t=0)")
+	}
+}
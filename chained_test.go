@@ -0,0 +1,107 @@
+package tsdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+// sampleSeriesIterator is a SeriesIterator over a fixed, time-sorted list
+// of samples, for testing iterator combinators without real chunks.
+type sampleSeriesIterator struct {
+	samples []sample
+	i       int
+}
+
+func newSampleSeriesIterator(ss ...sample) *sampleSeriesIterator {
+	return &sampleSeriesIterator{samples: ss, i: -1}
+}
+
+func (it *sampleSeriesIterator) Seek(t int64) bool {
+	for it.Next() {
+		if ct, _ := it.Values(); ct >= t {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *sampleSeriesIterator) Next() bool {
+	if it.i+1 >= len(it.samples) {
+		return false
+	}
+	it.i++
+	return true
+}
+
+func (it *sampleSeriesIterator) Values() (int64, float64) {
+	s := it.samples[it.i]
+	return s.t, s.v
+}
+
+func (it *sampleSeriesIterator) Err() error { return nil }
+
+func newTestChainedSeriesIterator() *chainedSeriesIterator {
+	return &chainedSeriesIterator{
+		series: []SeriesIterator{
+			newSampleSeriesIterator(sample{0, 0}, sample{10, 1}, sample{20, 2}),
+			newSampleSeriesIterator(sample{30, 3}, sample{40, 4}),
+		},
+		mints: []int64{0, 30},
+	}
+}
+
+func TestChainedSeriesIteratorNext(t *testing.T) {
+	it := newTestChainedSeriesIterator()
+
+	var got []sample
+	for it.Next() {
+		ts, v := it.Values()
+		got = append(got, sample{ts, v})
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []sample{{0, 0}, {10, 1}, {20, 2}, {30, 3}, {40, 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Next() produced %v, want %v", got, want)
+	}
+}
+
+func TestChainedSeriesIteratorSeek(t *testing.T) {
+	it := newTestChainedSeriesIterator()
+
+	if !it.Seek(25) {
+		t.Fatalf("Seek(25) = false, want true")
+	}
+	if ts, v := it.Values(); ts != 30 || v != 3 {
+		t.Errorf("Seek(25) landed on (%d, %v), want (30, 3)", ts, v)
+	}
+	if !it.Seek(35) {
+		t.Fatalf("Seek(35) = false, want true")
+	}
+	if ts, v := it.Values(); ts != 40 || v != 4 {
+		t.Errorf("Seek(35) landed on (%d, %v), want (40, 4)", ts, v)
+	}
+	if it.Seek(100) {
+		t.Fatalf("Seek(100) = true, want false")
+	}
+}
+
+func TestMergedSeriesIterator(t *testing.T) {
+	it := newMergedSeriesIterator(dedupPreferFirst,
+		newSampleSeriesIterator(sample{0, 0}, sample{10, 1}, sample{20, 2}),
+		newSampleSeriesIterator(sample{10, 100}, sample{15, 1.5}),
+	)
+	var got []sample
+	for it.Next() {
+		ts, v := it.Values()
+		got = append(got, sample{ts, v})
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []sample{{0, 0}, {10, 1}, {15, 1.5}, {20, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged samples = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+package tsdb
+
+import (
+	"regexp"
+	"regexp/syntax"
+)
+
+// regexpMatcher matches a string against a compiled regular expression.
+type regexpMatcher struct {
+	name string
+	re   *regexp.Regexp
+
+	// set holds the literal values the expression matches if it parses
+	// to a plain alternation of literals (e.g. "prod|staging"), and is nil
+	// otherwise. The parser factors alternatives with a common prefix, so
+	// a pattern like "foo|bar|baz" (parsed as "foo|ba[rz]") leaves set nil.
+	set []string
+}
+
+// MatchRegexp returns a matcher that matches the label name against the
+// given regular expression pattern. The pattern is anchored so that it
+// must match the whole value.
+func MatchRegexp(n, pattern string) (Matcher, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+	m := &regexpMatcher{name: n, re: re}
+
+	if parsed, err := syntax.Parse(pattern, syntax.Perl); err == nil {
+		m.set = regexpAlternates(parsed.Simplify())
+	}
+	return m, nil
+}
+
+func (m *regexpMatcher) Name() string        { return m.name }
+func (m *regexpMatcher) Match(v string) bool { return m.re.MatchString(v) }
+
+// Matches implements setMatcher. It returns nil unless the pattern is a
+// plain alternation of literal values, in which case selectSingle can
+// resolve postings for each value directly.
+func (m *regexpMatcher) Matches() []string { return m.set }
+
+// regexpAlternates returns the literal values re matches if it is made up
+// entirely of literals and alternations of literals, or nil otherwise.
+func regexpAlternates(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		// A case-insensitive literal matches more than its own spelling.
+		if re.Flags&syntax.FoldCase != 0 {
+			return nil
+		}
+		return []string{string(re.Rune)}
+	case syntax.OpCapture:
+		return regexpAlternates(re.Sub[0])
+	case syntax.OpAlternate:
+		res := make([]string, 0, len(re.Sub))
+		for _, sub := range re.Sub {
+			lits := regexpAlternates(sub)
+			if lits == nil {
+				return nil
+			}
+			res = append(res, lits...)
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+// valueSetMatcher matches a fixed, finite set of values.
+type valueSetMatcher struct {
+	name   string
+	values map[string]struct{}
+}
+
+// MatchSet returns a matcher that matches the label name against a fixed
+// set of values.
+func MatchSet(n string, vals ...string) Matcher {
+	sm := &valueSetMatcher{
+		name:   n,
+		values: make(map[string]struct{}, len(vals)),
+	}
+	for _, v := range vals {
+		sm.values[v] = struct{}{}
+	}
+	return sm
+}
+
+func (m *valueSetMatcher) Name() string { return m.name }
+
+func (m *valueSetMatcher) Match(v string) bool {
+	_, ok := m.values[v]
+	return ok
+}
+
+// Matches implements setMatcher.
+func (m *valueSetMatcher) Matches() []string {
+	res := make([]string, 0, len(m.values))
+	for v := range m.values {
+		res = append(res, v)
+	}
+	return res
+}
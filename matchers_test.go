@@ -0,0 +1,66 @@
+package tsdb
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMatchRegexp(t *testing.T) {
+	m, err := MatchRegexp("env", "prod|staging")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !m.Match("prod") || !m.Match("staging") {
+		t.Errorf("expected prod and staging to match")
+	}
+	if m.Match("dev") {
+		t.Errorf("did not expect dev to match")
+	}
+
+	sm, ok := m.(setMatcher)
+	if !ok {
+		t.Fatalf("regexpMatcher does not implement setMatcher")
+	}
+	got := sm.Matches()
+	sort.Strings(got)
+	want := []string{"prod", "staging"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Matches() = %v, want %v", got, want)
+	}
+}
+
+func TestMatchRegexpNonLiteral(t *testing.T) {
+	m, err := MatchRegexp("env", "pro.*")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !m.Match("prod") {
+		t.Errorf("expected prod to match")
+	}
+	if sm := m.(*regexpMatcher).Matches(); sm != nil {
+		t.Errorf("Matches() = %v, want nil for a non-literal pattern", sm)
+	}
+}
+
+func TestMatchSet(t *testing.T) {
+	m := MatchSet("env", "prod", "staging")
+
+	if !m.Match("prod") || !m.Match("staging") {
+		t.Errorf("expected prod and staging to match")
+	}
+	if m.Match("dev") {
+		t.Errorf("did not expect dev to match")
+	}
+
+	sm, ok := m.(setMatcher)
+	if !ok {
+		t.Fatalf("valueSetMatcher does not implement setMatcher")
+	}
+	got := sm.Matches()
+	sort.Strings(got)
+	want := []string{"prod", "staging"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Matches() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package tsdb
+
+// Merge returns a new iterator over the union of the input iterators.
+// It is the dual of Intersect: at each step it advances every iterator
+// currently positioned at the overall minimum value.
+func Merge(its ...Iterator) Iterator {
+	switch len(its) {
+	case 0:
+		return errIterator{}
+	case 1:
+		return its[0]
+	}
+	return &mergeIterator{its: its}
+}
+
+type mergeIterator struct {
+	its  []Iterator
+	done []bool
+
+	initialized bool
+	cur         uint32
+	err         error
+}
+
+func (it *mergeIterator) init() {
+	it.initialized = true
+	it.done = make([]bool, len(it.its))
+
+	for i, sub := range it.its {
+		it.advance(i, sub)
+	}
+}
+
+// advance moves the i-th sub-iterator forward by one position, recording
+// whether it has been exhausted.
+func (it *mergeIterator) advance(i int, sub Iterator) {
+	if sub.Next() {
+		return
+	}
+	if err := sub.Err(); err != nil {
+		it.err = err
+	}
+	it.done[i] = true
+}
+
+func (it *mergeIterator) Next() bool {
+	if !it.initialized {
+		it.init()
+	} else {
+		// Advance every iterator that produced the previous minimum; all
+		// others are already positioned beyond it.
+		for i, sub := range it.its {
+			if !it.done[i] && sub.Value() == it.cur {
+				it.advance(i, sub)
+			}
+		}
+	}
+	if it.err != nil {
+		return false
+	}
+
+	min := uint32(0)
+	found := false
+
+	for i, sub := range it.its {
+		if it.done[i] {
+			continue
+		}
+		if v := sub.Value(); !found || v < min {
+			min = v
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+	it.cur = min
+	return true
+}
+
+func (it *mergeIterator) Seek(v uint32) bool {
+	if !it.initialized {
+		// cur is only meaningful once Next has run at least once; without
+		// this, Seek(0) would return true on an un-positioned iterator and
+		// Value() would report the zero value instead of an actual posting.
+		if !it.Next() {
+			return false
+		}
+	}
+	for it.cur < v {
+		if !it.Next() {
+			return false
+		}
+	}
+	return true
+}
+
+func (it *mergeIterator) Value() uint32 { return it.cur }
+func (it *mergeIterator) Err() error    { return it.err }
@@ -0,0 +1,64 @@
+package tsdb
+
+import "testing"
+
+func TestMergeIterator(t *testing.T) {
+	it := Merge(
+		newFakeIterator(1, 3, 5),
+		newFakeIterator(2, 3, 6),
+	)
+	var got []uint32
+	for it.Next() {
+		got = append(got, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []uint32{1, 2, 3, 5, 6}
+	if len(got) != len(want) {
+		t.Fatalf("Merge() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Merge() = %v, want %v", got, want)
+		}
+	}
+}
+
+// TestMergeIteratorSeekUninitialized guards against a Seek call landing on
+// an iterator that has never had Next called: Seek(v) for v <= the zero
+// value must not report the zero value as a match before init() has run.
+func TestMergeIteratorSeekUninitialized(t *testing.T) {
+	it := Merge(
+		newFakeIterator(1, 3, 5),
+		newFakeIterator(2, 4),
+	)
+	if !it.Seek(0) {
+		t.Fatalf("Seek(0) = false, want true")
+	}
+	if v := it.Value(); v != 1 {
+		t.Errorf("Seek(0) landed on %d, want 1", v)
+	}
+}
+
+func TestMergeIteratorSeek(t *testing.T) {
+	it := Merge(
+		newFakeIterator(1, 3, 5),
+		newFakeIterator(2, 4, 6),
+	)
+	if !it.Seek(4) {
+		t.Fatalf("Seek(4) = false, want true")
+	}
+	if v := it.Value(); v != 4 {
+		t.Errorf("Seek(4) landed on %d, want 4", v)
+	}
+	if !it.Seek(6) {
+		t.Fatalf("Seek(6) = false, want true")
+	}
+	if v := it.Value(); v != 6 {
+		t.Errorf("Seek(6) landed on %d, want 6", v)
+	}
+	if it.Seek(7) {
+		t.Errorf("Seek(7) = true, want false")
+	}
+}
@@ -0,0 +1,134 @@
+// Package prometheus adapts this repository's Querier/Series/SeriesIterator
+// types to the semantics expected by Prometheus' storage/local.Storage
+// interface, so a tsdb.DB can be plugged in as its storage engine. It is
+// kept separate from the core tsdb package so that package stays free of
+// a dependency on prometheus/common and prometheus/prometheus.
+package prometheus
+
+import (
+	"github.com/fabxc/tsdb"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/storage/metric"
+)
+
+// Querier adapts a tsdb.Querier to return storage/local-compatible series
+// iterators instead of raw tsdb.Series.
+type Querier struct {
+	q tsdb.Querier
+}
+
+// NewQuerier wraps q for consumption through the local.SeriesIterator API.
+func NewQuerier(q tsdb.Querier) *Querier {
+	return &Querier{q: q}
+}
+
+// Select returns a local.SeriesIterator for every series matching ms.
+func (q *Querier) Select(ms ...tsdb.Matcher) ([]*DefaultSeriesIterator, error) {
+	set := q.q.Select(ms...)
+
+	var res []*DefaultSeriesIterator
+	for set.Next() {
+		res = append(res, NewSeriesIterator(set.Series()))
+	}
+	return res, set.Err()
+}
+
+// Close releases the resources held by the underlying tsdb.Querier.
+func (q *Querier) Close() error { return q.q.Close() }
+
+// DefaultSeriesIterator implements Prometheus' storage/local.SeriesIterator
+// on top of a tsdb.Series.
+type DefaultSeriesIterator struct {
+	series tsdb.Series
+	it     tsdb.SeriesIterator
+}
+
+// NewSeriesIterator returns a local.SeriesIterator over s.
+func NewSeriesIterator(s tsdb.Series) *DefaultSeriesIterator {
+	return &DefaultSeriesIterator{
+		series: s,
+		it:     s.Iterator(),
+	}
+}
+
+// Metric implements local.SeriesIterator.
+func (it *DefaultSeriesIterator) Metric() metric.Metric {
+	return metric.Metric{Metric: toModelMetric(it.series.Labels())}
+}
+
+// ValueAtOrBeforeTime implements local.SeriesIterator. local.SeriesIterator
+// callers invoke this repeatedly with arbitrary, often non-monotonic
+// timestamps per series, so it always works off a fresh iterator rather
+// than the shared, forward-only it.it, which a previous call may have left
+// positioned past the timestamp this call is asking about.
+func (it *DefaultSeriesIterator) ValueAtOrBeforeTime(t model.Time) model.SamplePair {
+	sit := it.series.Iterator()
+
+	// The common case: tsdb's Seek lands exactly on t, which is guaranteed
+	// to be the first sample at-or-after t.
+	if sit.Seek(int64(t)) {
+		if ts, v := sit.Values(); int64(ts) == int64(t) {
+			return model.SamplePair{Timestamp: model.Time(ts), Value: model.SampleValue(v)}
+		}
+	}
+
+	// Seek either overshot t (landed on the first sample strictly after
+	// it) or found nothing at or after t at all. Either way, the sample we
+	// want, if any, is the last one at or before t; tsdb.SeriesIterator
+	// can't step backward, so finding it takes a full forward scan from a
+	// new iterator.
+	var (
+		prev     model.SamplePair
+		havePrev bool
+	)
+	for sit := it.series.Iterator(); sit.Next(); {
+		ts, v := sit.Values()
+		if int64(ts) > int64(t) {
+			break
+		}
+		prev = model.SamplePair{Timestamp: model.Time(ts), Value: model.SampleValue(v)}
+		havePrev = true
+	}
+	if !havePrev {
+		// tsdb.SeriesIterator does not expose a "no value at all" sentinel
+		// distinct from the zero time, so a missing sample surfaces as
+		// model.Earliest, matching what local.SeriesIterator implementations
+		// return when a series has no data at or before t.
+		return model.SamplePair{Timestamp: model.Earliest, Value: 0}
+	}
+	return prev
+}
+
+// RangeValues implements local.SeriesIterator, clamping to the requested
+// interval.
+func (it *DefaultSeriesIterator) RangeValues(in metric.Interval) []model.SamplePair {
+	var res []model.SamplePair
+
+	if !it.it.Seek(int64(in.OldestInclusive)) {
+		return res
+	}
+	for {
+		t, v := it.it.Values()
+		if int64(t) > int64(in.NewestInclusive) {
+			break
+		}
+		res = append(res, model.SamplePair{Timestamp: model.Time(t), Value: model.SampleValue(v)})
+		if !it.it.Next() {
+			break
+		}
+	}
+	return res
+}
+
+// Close implements local.SeriesIterator.
+func (it *DefaultSeriesIterator) Close() {}
+
+// toModelMetric converts tsdb's Labels into a Prometheus model.Metric.
+func toModelMetric(lset tsdb.Labels) model.Metric {
+	m := make(model.Metric, len(lset))
+	for _, l := range lset {
+		m[model.LabelName(l.Name)] = model.LabelValue(l.Value)
+	}
+	return m
+}
@@ -0,0 +1,123 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/fabxc/tsdb"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/storage/metric"
+)
+
+// testSample is a plain timestamp/value pair used to seed fakeSeriesIterator;
+// it has no relation to any tsdb-internal sample representation.
+type testSample struct {
+	t int64
+	v float64
+}
+
+// fakeSeries is a minimal tsdb.Series backed by a fixed, sorted list of
+// samples, for exercising the adapter without a real block.
+type fakeSeries struct {
+	lset    tsdb.Labels
+	samples []testSample
+}
+
+func (s *fakeSeries) Labels() tsdb.Labels { return s.lset }
+
+func (s *fakeSeries) Iterator() tsdb.SeriesIterator {
+	return &fakeSeriesIterator{samples: s.samples, i: -1}
+}
+
+type fakeSeriesIterator struct {
+	samples []testSample
+	i       int
+}
+
+func (it *fakeSeriesIterator) Seek(t int64) bool {
+	for it.Next() {
+		if s, _ := it.Values(); s >= t {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *fakeSeriesIterator) Next() bool {
+	if it.i+1 >= len(it.samples) {
+		return false
+	}
+	it.i++
+	return true
+}
+
+func (it *fakeSeriesIterator) Values() (t int64, v float64) {
+	s := it.samples[it.i]
+	return s.t, s.v
+}
+
+func (it *fakeSeriesIterator) Err() error { return nil }
+
+func newTestSeries() *fakeSeries {
+	return &fakeSeries{
+		lset: tsdb.Labels{{Name: "__name__", Value: "metric"}},
+		samples: []testSample{
+			{t: 0, v: 0}, {t: 10, v: 1}, {t: 20, v: 2}, {t: 30, v: 3},
+		},
+	}
+}
+
+func TestDefaultSeriesIteratorValueAtOrBeforeTime(t *testing.T) {
+	it := NewSeriesIterator(newTestSeries())
+
+	sp := it.ValueAtOrBeforeTime(15)
+	if sp.Timestamp != 10 || sp.Value != 1 {
+		t.Errorf("ValueAtOrBeforeTime(15) = %v, want (10, 1)", sp)
+	}
+}
+
+func TestDefaultSeriesIteratorValueAtOrBeforeTimeNoData(t *testing.T) {
+	it := NewSeriesIterator(newTestSeries())
+
+	sp := it.ValueAtOrBeforeTime(-1)
+	if sp.Timestamp != model.Earliest {
+		t.Errorf("ValueAtOrBeforeTime(-1) = %v, want model.Earliest", sp)
+	}
+}
+
+// TestDefaultSeriesIteratorValueAtOrBeforeTimeRepeatedCalls guards against a
+// regression where ValueAtOrBeforeTime kept advancing a single shared,
+// forward-only iterator: a later call for an earlier or already-passed
+// timestamp must still see the correct sample, not whatever the previous
+// call left the iterator pointed at.
+func TestDefaultSeriesIteratorValueAtOrBeforeTimeRepeatedCalls(t *testing.T) {
+	it := NewSeriesIterator(newTestSeries())
+
+	if sp := it.ValueAtOrBeforeTime(30); sp.Timestamp != 30 || sp.Value != 3 {
+		t.Fatalf("ValueAtOrBeforeTime(30) = %v, want (30, 3)", sp)
+	}
+	if sp := it.ValueAtOrBeforeTime(15); sp.Timestamp != 10 || sp.Value != 1 {
+		t.Errorf("ValueAtOrBeforeTime(15) after a later call = %v, want (10, 1)", sp)
+	}
+	if sp := it.ValueAtOrBeforeTime(5); sp.Timestamp != 0 || sp.Value != 0 {
+		t.Errorf("ValueAtOrBeforeTime(5) after a later call = %v, want (0, 0)", sp)
+	}
+}
+
+func TestDefaultSeriesIteratorRangeValues(t *testing.T) {
+	it := NewSeriesIterator(newTestSeries())
+
+	got := it.RangeValues(metric.Interval{OldestInclusive: 10, NewestInclusive: 20})
+	want := []model.SamplePair{
+		{Timestamp: 10, Value: 1},
+		{Timestamp: 20, Value: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("RangeValues() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("RangeValues() = %v, want %v", got, want)
+		}
+	}
+}
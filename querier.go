@@ -1,7 +1,10 @@
 package tsdb
 
 import (
+	"container/heap"
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 
 	"github.com/fabxc/tsdb/chunks"
@@ -26,6 +29,17 @@ func MatchEquals(n, v string) Matcher {
 func (m *equalMatcher) Name() string        { return m.name }
 func (m *equalMatcher) Match(v string) bool { return v == m.value }
 
+// Matches implements setMatcher.
+func (m *equalMatcher) Matches() []string { return []string{m.value} }
+
+// setMatcher is implemented by matchers that can enumerate the exact,
+// finite set of values they match. selectSingle uses it to look up
+// postings for each candidate value directly instead of scanning the
+// full set of label values.
+type setMatcher interface {
+	Matches() []string
+}
+
 // Querier provides querying access over time series data of a fixed
 // time range.
 type Querier interface {
@@ -37,6 +51,9 @@ type Querier interface {
 	// LabelValuesFor returns all potential values for a label name.
 	// under the constraint of another label.
 	LabelValuesFor(string, Label) ([]string, error)
+	// LabelNames returns all the unique label names present in the block
+	// in sorted order.
+	LabelNames() ([]string, error)
 
 	// Close releases the resources of the Querier.
 	Close() error
@@ -94,18 +111,104 @@ func (q *querier) Select(ms ...Matcher) SeriesSet {
 	return r
 }
 
-func (q *querier) LabelValues(string) ([]string, error) {
-	return nil, nil
+func (q *querier) LabelValues(n string) ([]string, error) {
+	if len(q.shards) == 0 {
+		return nil, nil
+	}
+	res, err := q.shards[0].LabelValues(n)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range q.shards[1:] {
+		pres, err := s.LabelValues(n)
+		if err != nil {
+			return nil, err
+		}
+		res = mergeStrings(res, pres)
+	}
+	return res, nil
+}
+
+func (q *querier) LabelValuesFor(n string, lbl Label) ([]string, error) {
+	if len(q.shards) == 0 {
+		return nil, nil
+	}
+	res, err := q.shards[0].LabelValuesFor(n, lbl)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range q.shards[1:] {
+		pres, err := s.LabelValuesFor(n, lbl)
+		if err != nil {
+			return nil, err
+		}
+		res = mergeStrings(res, pres)
+	}
+	return res, nil
 }
 
-func (q *querier) LabelValuesFor(string, Label) ([]string, error) {
-	return nil, fmt.Errorf("not implemented")
+func (q *querier) LabelNames() ([]string, error) {
+	if len(q.shards) == 0 {
+		return nil, nil
+	}
+	res, err := q.shards[0].LabelNames()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range q.shards[1:] {
+		pres, err := s.LabelNames()
+		if err != nil {
+			return nil, err
+		}
+		res = mergeStrings(res, pres)
+	}
+	return res, nil
 }
 
 func (q *querier) Close() error {
 	return nil
 }
 
+// mergeStrings merges two sorted, duplicate-free string slices into a
+// single sorted, duplicate-free slice.
+func mergeStrings(a, b []string) []string {
+	res := make([]string, 0, len(a)+len(b))
+
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			res = append(res, a[0])
+			a = a[1:]
+		case a[0] > b[0]:
+			res = append(res, b[0])
+			b = b[1:]
+		default:
+			res = append(res, a[0])
+			a, b = a[1:], b[1:]
+		}
+	}
+	res = append(res, a...)
+	res = append(res, b...)
+
+	return res
+}
+
+// overlapsSorted reports whether it shares at least one value with the
+// ascending-sorted slice of references in sorted.
+func overlapsSorted(it Iterator, sorted []uint32) (bool, error) {
+	j := 0
+	for it.Next() {
+		v := it.Value()
+		for j < len(sorted) && sorted[j] < v {
+			j++
+		}
+		if j < len(sorted) && sorted[j] == v {
+			return true, nil
+		}
+	}
+	return false, it.Err()
+}
+
 // shardQuerier aggregates querying results from time blocks within
 // a single shard.
 type shardQuerier struct {
@@ -262,12 +365,60 @@ func (q *shardQuerier) Select(ms ...Matcher) SeriesSet {
 	return r
 }
 
-func (q *shardQuerier) LabelValues(string) ([]string, error) {
-	return nil, nil
+func (q *shardQuerier) LabelValues(n string) ([]string, error) {
+	if len(q.blocks) == 0 {
+		return nil, nil
+	}
+	res, err := q.blocks[0].LabelValues(n)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range q.blocks[1:] {
+		pres, err := b.LabelValues(n)
+		if err != nil {
+			return nil, err
+		}
+		// Blocks are lexicographically sorted but may repeat values across
+		// block boundaries, so we still have to merge-dedupe them.
+		res = mergeStrings(res, pres)
+	}
+	return res, nil
+}
+
+func (q *shardQuerier) LabelValuesFor(n string, lbl Label) ([]string, error) {
+	if len(q.blocks) == 0 {
+		return nil, nil
+	}
+	res, err := q.blocks[0].LabelValuesFor(n, lbl)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range q.blocks[1:] {
+		pres, err := b.LabelValuesFor(n, lbl)
+		if err != nil {
+			return nil, err
+		}
+		res = mergeStrings(res, pres)
+	}
+	return res, nil
 }
 
-func (q *shardQuerier) LabelValuesFor(string, Label) ([]string, error) {
-	return nil, fmt.Errorf("not implemented")
+func (q *shardQuerier) LabelNames() ([]string, error) {
+	if len(q.blocks) == 0 {
+		return nil, nil
+	}
+	res, err := q.blocks[0].LabelNames()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range q.blocks[1:] {
+		pres, err := b.LabelNames()
+		if err != nil {
+			return nil, err
+		}
+		res = mergeStrings(res, pres)
+	}
+	return res, nil
 }
 
 func (q *shardQuerier) Close() error {
@@ -276,42 +427,199 @@ func (q *shardQuerier) Close() error {
 
 // blockQuerier provides querying access to a single block database.
 type blockQuerier struct {
-	index  IndexReader
-	series SeriesReader
+	index      IndexReader
+	chunks     ChunkReader
+	tombstones TombstoneReader
 
 	mint, maxt int64
 }
 
-func newBlockQuerier(ix IndexReader, s SeriesReader, mint, maxt int64) *blockQuerier {
+func newBlockQuerier(ix IndexReader, c ChunkReader, tr TombstoneReader, mint, maxt int64) *blockQuerier {
 	return &blockQuerier{
-		mint:   mint,
-		maxt:   maxt,
-		index:  ix,
-		series: s,
+		mint:       mint,
+		maxt:       maxt,
+		index:      ix,
+		chunks:     c,
+		tombstones: tr,
 	}
 }
 
+// mixedMatcher is a matcher that matches both the empty value and at least
+// one non-empty value, e.g. MatchSet("env", "", "prod") or a regex like
+// "prod|". Select has to honor both halves: series carrying a matching
+// non-empty value, and series where the label is absent altogether.
+type mixedMatcher struct {
+	name  string
+	itIdx int // index into its of this matcher's selectSingle result
+}
+
 func (q *blockQuerier) Select(ms ...Matcher) SeriesSet {
-	var its []Iterator
+	its, absent, mixed := q.classifyMatchers(ms)
+
+	newSet := func(its []Iterator, absent []string) SeriesSet {
+		return &populatedChunkSeries{
+			set:        newBaseChunkSeries(Intersect(its...), q.index, absent),
+			chunks:     q.chunks,
+			tombstones: q.tombstones,
+			mint:       q.mint,
+			maxt:       q.maxt,
+		}
+	}
+
+	itsBranches, absentBranches := mixedSelectBranches(its, absent, mixed)
+
+	sets := make([]SeriesSet, len(itsBranches))
+	for i := range itsBranches {
+		if i > 0 {
+			// Postings iterators are consumed as they are read, so every
+			// further branch takes its own from a fresh classification.
+			its, absent, mixed := q.classifyMatchers(ms)
+			fits, fabsent := mixedSelectBranches(its, absent, mixed)
+			itsBranches[i], absentBranches[i] = fits[i], fabsent[i]
+		}
+		sets[i] = newSet(itsBranches[i], absentBranches[i])
+	}
+	if len(sets) == 1 {
+		return sets[0]
+	}
+	return &mergedSeriesSet{sets: sets}
+}
+
+// classifyMatchers splits ms into the pieces Select (and anything else
+// that needs the same matching semantics, like Block.Delete) builds a
+// result from: its holds the postings iterators for matchers that must
+// always be satisfied, absent holds the label names that must never be
+// set on a candidate series, and mixed holds the matchers for which a
+// series can satisfy either condition — a matching value in its, or the
+// label's absence.
+func (q *blockQuerier) classifyMatchers(ms []Matcher) (its []Iterator, absent []string, mixed []mixedMatcher) {
 	for _, m := range ms {
+		if !m.Match("") {
+			its = append(its, q.selectSingle(m))
+			continue
+		}
+		// A matcher that matches the empty value can never be fully
+		// satisfied through postings, since the index only carries entries
+		// for values that actually occur. If it can enumerate its matching
+		// values and they're all empty, it asserts that the label must be
+		// absent from the series altogether. Otherwise — it also matches
+		// some non-empty value, or we can't enumerate it to tell — both
+		// series carrying a matching value and series missing the label
+		// entirely are valid matches, so selectSingle still runs and the
+		// absent branch is unioned in separately by the caller.
+		if sm, ok := m.(setMatcher); ok && isEmptyOnly(sm.Matches()) {
+			absent = append(absent, m.Name())
+			continue
+		}
 		its = append(its, q.selectSingle(m))
+		mixed = append(mixed, mixedMatcher{name: m.Name(), itIdx: len(its) - 1})
+	}
+	return its, absent, mixed
+}
+
+// selectRefs returns the iterator of series references matching ms, using
+// the same absent/mixed-matcher handling as Select. Unlike Select, it
+// yields bare references instead of populated series, since callers like
+// Block.Delete only need to know which series matched.
+func (q *blockQuerier) selectRefs(ms ...Matcher) Iterator {
+	its, absent, mixed := q.classifyMatchers(ms)
+	itsBranches, absentBranches := mixedSelectBranches(its, absent, mixed)
+
+	branches := make([]Iterator, len(itsBranches))
+	for i := range itsBranches {
+		if i > 0 {
+			// As in Select, every further branch needs postings iterators
+			// of its own.
+			its, absent, mixed := q.classifyMatchers(ms)
+			fits, fabsent := mixedSelectBranches(its, absent, mixed)
+			itsBranches[i], absentBranches[i] = fits[i], fabsent[i]
+		}
+		branches[i] = &refIterator{
+			base: newBaseChunkSeries(Intersect(itsBranches[i]...), q.index, absentBranches[i]),
+		}
+	}
+	return Merge(branches...)
+}
+
+// refIterator adapts a baseChunkSeries to the Iterator interface, walking
+// its matches without populating chunk data.
+type refIterator struct {
+	base *baseChunkSeries
+}
+
+func (it *refIterator) Next() bool    { return it.base.Next() }
+func (it *refIterator) Value() uint32 { return it.base.Ref() }
+func (it *refIterator) Err() error    { return it.base.Err() }
+
+// mixedSelectBranches enumerates the (its, absent) pair for every branch a
+// set of mixed matchers produces. Each mixed matcher independently
+// contributes either its selectSingle iterator (label present with a
+// matching value) or its name to the absent list (label missing), and a
+// series can satisfy any combination of those choices across the mixed
+// matchers, so every one of the 2^len(mixed) combinations needs its own
+// branch; unioning only the single-absent branches would miss series that
+// are absent for two or more mixed matchers at once.
+func mixedSelectBranches(its []Iterator, absent []string, mixed []mixedMatcher) ([][]Iterator, [][]string) {
+	nbranches := 1 << uint(len(mixed))
+	itsOut := make([][]Iterator, 0, nbranches)
+	absentOut := make([][]string, 0, nbranches)
+
+	for bits := 0; bits < nbranches; bits++ {
+		excl := make([]bool, len(its))
+		// Copy rather than append onto the shared `absent` backing array:
+		// each branch needs its own slice, or a later iteration's append
+		// could silently overwrite an earlier branch's entry in place.
+		branchAbsent := append([]string(nil), absent...)
+
+		for i, mm := range mixed {
+			if bits&(1<<uint(i)) != 0 {
+				excl[mm.itIdx] = true
+				branchAbsent = append(branchAbsent, mm.name)
+			}
+		}
+		branchIts := make([]Iterator, 0, len(its))
+		for i, it := range its {
+			if !excl[i] {
+				branchIts = append(branchIts, it)
+			}
+		}
+		itsOut = append(itsOut, branchIts)
+		absentOut = append(absentOut, branchAbsent)
 	}
+	return itsOut, absentOut
+}
 
-	// TODO(fabxc): pass down time range so the series iterator
-	// can be instantiated with it?
-	return &blockSeriesSet{
-		index: q.index,
-		it:    Intersect(its...),
+// isEmptyOnly reports whether vals is a non-empty enumeration consisting of
+// nothing but the empty string, i.e. the matcher it came from can never
+// match a real label value. A nil vals means the matcher couldn't enumerate
+// its values at all, which isEmptyOnly must not mistake for "empty-only".
+func isEmptyOnly(vals []string) bool {
+	if len(vals) == 0 {
+		return false
+	}
+	for _, v := range vals {
+		if v != "" {
+			return false
+		}
 	}
+	return true
 }
 
 func (q *blockQuerier) selectSingle(m Matcher) Iterator {
+	// If the matcher can enumerate its own finite set of matching values,
+	// skip the full label-values scan and look up postings for each value
+	// directly. For pure equality this collapses to a single Postings call.
+	// A nil result means the matcher couldn't enumerate at all (e.g. a
+	// non-literal regex) rather than that it matches nothing, so that case
+	// must fall through to the scan below instead of taking the fast path.
+	if sm, ok := m.(setMatcher); ok && sm.Matches() != nil {
+		return q.postingsForValues(m.Name(), sm.Matches())
+	}
+
 	tpls, err := q.index.LabelValues(m.Name())
 	if err != nil {
 		return errIterator{err: err}
 	}
-	// TODO(fabxc): use interface upgrading to provide fast solution
-	// for equality and prefix matches. Tuples are lexicographically sorted.
 	var res []string
 
 	for i := 0; i < tpls.Len(); i++ {
@@ -324,17 +632,22 @@ func (q *blockQuerier) selectSingle(m Matcher) Iterator {
 		}
 	}
 
-	var rit Iterator
+	return q.postingsForValues(m.Name(), res)
+}
+
+// postingsForValues returns the union of the postings lists for name=value,
+// for every value in vals.
+func (q *blockQuerier) postingsForValues(name string, vals []string) Iterator {
+	its := make([]Iterator, 0, len(vals))
 
-	for _, v := range res {
-		it, err := q.index.Postings(m.Name(), v)
+	for _, v := range vals {
+		it, err := q.index.Postings(name, v)
 		if err != nil {
 			return errIterator{err: err}
 		}
-		rit = Intersect(rit, it)
+		its = append(its, it)
 	}
-
-	return rit
+	return Merge(its...)
 }
 
 func (q *blockQuerier) LabelValues(name string) ([]string, error) {
@@ -351,46 +664,282 @@ func (q *blockQuerier) LabelValues(name string) ([]string, error) {
 		}
 		res = append(res, vals[0])
 	}
-	return nil, nil
+	return res, nil
 }
 
-func (q *blockQuerier) LabelValuesFor(string, Label) ([]string, error) {
-	return nil, fmt.Errorf("not implemented")
+func (q *blockQuerier) LabelValuesFor(name string, lbl Label) ([]string, error) {
+	tpls, err := q.index.LabelValues(name)
+	if err != nil {
+		return nil, err
+	}
+	// lbl never changes across candidate values, so materialize its
+	// postings once instead of re-querying and re-intersecting the index
+	// for every value of name.
+	cit, err := q.index.Postings(lbl.Name, lbl.Value)
+	if err != nil {
+		return nil, err
+	}
+	var constraint []uint32
+	for cit.Next() {
+		constraint = append(constraint, cit.Value())
+	}
+	if cit.Err() != nil {
+		return nil, cit.Err()
+	}
+
+	var res []string
+
+	for i := 0; i < tpls.Len(); i++ {
+		vals, err := tpls.At(i)
+		if err != nil {
+			return nil, err
+		}
+		p, err := q.index.Postings(name, vals[0])
+		if err != nil {
+			return nil, err
+		}
+		ok, err := overlapsSorted(p, constraint)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res = append(res, vals[0])
+		}
+	}
+	return res, nil
+}
+
+func (q *blockQuerier) LabelNames() ([]string, error) {
+	tpls, err := q.index.LabelIndices()
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, t := range tpls {
+		// Composite indices spanning more than one label don't represent
+		// a label name on their own.
+		if len(t) != 1 {
+			continue
+		}
+		res = append(res, t[0])
+	}
+	sort.Strings(res)
+	return res, nil
 }
 
 func (q *blockQuerier) Close() error {
 	return nil
 }
 
-// blockSeriesSet is a set of series from an inverted index query.
-type blockSeriesSet struct {
-	index IndexReader
-	it    Iterator
+// ChunkMeta describes a chunk of data for a series as stored in a block's
+// index, without necessarily holding its data in memory.
+type ChunkMeta struct {
+	// Ref is the reference used to look the chunk's data up through a
+	// ChunkReader.
+	Ref uint64
+
+	MinTime, MaxTime int64
+}
+
+// ChunkReader provides reading access to the chunk data of a data block.
+type ChunkReader interface {
+	// Chunk returns the chunk for the given reference.
+	Chunk(ref uint64) (chunks.Chunk, error)
+
+	// Close releases all underlying resources of the reader.
+	Close() error
+}
+
+// baseChunkSeries iterates over the postings resulting from a set of
+// matchers and yields the raw (Labels, []ChunkMeta) pair stored in the
+// index for every matching series, filtering out any series that carries
+// a label required to be absent.
+type baseChunkSeries struct {
+	it     Iterator
+	index  IndexReader
+	absent []string // label names that must not be set on the series
+
+	lset   Labels
+	chunks []ChunkMeta
+	ref    uint32
+	err    error
+}
+
+func newBaseChunkSeries(it Iterator, index IndexReader, absent []string) *baseChunkSeries {
+	return &baseChunkSeries{it: it, index: index, absent: absent}
+}
+
+func (s *baseChunkSeries) At() (Labels, []ChunkMeta) { return s.lset, s.chunks }
+func (s *baseChunkSeries) Ref() uint32               { return s.ref }
+func (s *baseChunkSeries) Err() error                { return s.err }
+
+func (s *baseChunkSeries) Next() bool {
+Outer:
+	for s.it.Next() {
+		ref := s.it.Value()
+
+		lset, chks, err := s.index.Series(ref)
+		if err != nil {
+			s.err = err
+			return false
+		}
+		for _, l := range s.absent {
+			if lset.Get(l) != "" {
+				continue Outer
+			}
+		}
+		s.ref = ref
+		s.lset = lset
+		s.chunks = chks
+		return true
+	}
+	if err := s.it.Err(); err != nil {
+		s.err = err
+	}
+	return false
+}
+
+// populatedChunkSeries turns a baseChunkSeries into a regular SeriesSet by
+// dropping chunks that fall fully outside [mint, maxt] and loading the
+// remaining ones from a ChunkReader.
+type populatedChunkSeries struct {
+	set        *baseChunkSeries
+	chunks     ChunkReader
+	tombstones TombstoneReader
+	mint, maxt int64
 
 	err error
 	cur Series
 }
 
-func (s *blockSeriesSet) Next() bool {
-	// Get next reference from postings iterator.
-	if !s.it.Next() {
-		s.err = s.it.Err()
-		return false
+func (s *populatedChunkSeries) Series() Series { return s.cur }
+
+func (s *populatedChunkSeries) Err() error {
+	if s.err != nil {
+		return s.err
 	}
+	return s.set.Err()
+}
 
-	// Resolve reference to series.
-	series, err := s.index.Series(s.it.Value())
-	if err != nil {
-		s.err = err
+func (s *populatedChunkSeries) Next() bool {
+	for s.set.Next() {
+		lset, chks := s.set.At()
+
+		for len(chks) > 0 && chks[0].MaxTime < s.mint {
+			chks = chks[1:]
+		}
+		for len(chks) > 0 && chks[len(chks)-1].MinTime > s.maxt {
+			chks = chks[:len(chks)-1]
+		}
+		if len(chks) == 0 {
+			continue
+		}
+
+		cs := make([]chunks.Chunk, 0, len(chks))
+		for _, cm := range chks {
+			c, err := s.chunks.Chunk(cm.Ref)
+			if err != nil {
+				s.err = err
+				return false
+			}
+			cs = append(cs, c)
+		}
+
+		var dranges []Interval
+		if s.tombstones != nil {
+			ivs, err := s.tombstones.Get(s.set.Ref())
+			if err != nil {
+				s.err = err
+				return false
+			}
+			dranges = ivs
+		}
+
+		s.cur = &chunkSeries{
+			labels:  lset,
+			chunks:  cs,
+			mint:    s.mint,
+			maxt:    s.maxt,
+			dranges: dranges,
+			minTime: chks[0].MinTime,
+		}
+		return true
+	}
+	return false
+}
+
+// chunkSeries is a Series backed by a populated, time-pruned set of chunks,
+// with any tombstoned intervals to be filtered out on read.
+type chunkSeries struct {
+	labels     Labels
+	chunks     []chunks.Chunk
+	mint, maxt int64
+	dranges    []Interval
+
+	// minTime is the min time of the first (oldest) pruned chunk, used by
+	// chainedSeriesIterator.Seek to binary search for the right series.
+	minTime int64
+}
+
+func (s *chunkSeries) Labels() Labels { return s.labels }
+
+// MinTime implements seriesMinTimer.
+func (s *chunkSeries) MinTime() int64 { return s.minTime }
+
+func (s *chunkSeries) Iterator() SeriesIterator {
+	it := SeriesIterator(&boundedSeriesIterator{
+		it:   newChunkSeriesIterator(s.chunks),
+		mint: s.mint,
+		maxt: s.maxt,
+	})
+	if len(s.dranges) > 0 {
+		it = &deletedSeriesIterator{it: it, dranges: s.dranges}
+	}
+	return it
+}
+
+// boundedSeriesIterator wraps a SeriesIterator and restricts it to
+// [mint, maxt], so Seek can skip whole chunks instead of scanning samples
+// one at a time.
+type boundedSeriesIterator struct {
+	it         SeriesIterator
+	mint, maxt int64
+	done       bool
+}
+
+func (it *boundedSeriesIterator) Seek(t int64) bool {
+	if t < it.mint {
+		t = it.mint
+	}
+	if t > it.maxt {
+		it.done = true
+		return false
+	}
+	if !it.it.Seek(t) {
+		it.done = true
+		return false
+	}
+	ts, _ := it.it.Values()
+	if ts > it.maxt {
+		it.done = true
 		return false
 	}
+	return true
+}
 
-	s.cur = series
+func (it *boundedSeriesIterator) Next() bool {
+	if it.done || !it.it.Next() {
+		return false
+	}
+	if ts, _ := it.it.Values(); ts > it.maxt {
+		it.done = true
+		return false
+	}
 	return true
 }
 
-func (s *blockSeriesSet) Series() Series { return s.cur }
-func (s *blockSeriesSet) Err() error     { return s.err }
+func (it *boundedSeriesIterator) Values() (t int64, v float64) { return it.it.Values() }
+func (it *boundedSeriesIterator) Err() error                   { return it.it.Err() }
 
 // SeriesIterator iterates over the data of a time series.
 type SeriesIterator interface {
@@ -414,12 +963,27 @@ func (s *chainedSeries) Labels() Labels {
 	return s.series[0].Labels()
 }
 
+// seriesMinTimer is implemented by Series that can report a lower bound on
+// their data's timestamps without materializing an iterator. It lets
+// chainedSeriesIterator.Seek binary-search for the relevant series instead
+// of scanning every preceding one sample by sample.
+type seriesMinTimer interface {
+	MinTime() int64
+}
+
 func (s *chainedSeries) Iterator() SeriesIterator {
 	it := &chainedSeriesIterator{
 		series: make([]SeriesIterator, 0, len(s.series)),
+		mints:  make([]int64, 0, len(s.series)),
 	}
 	for _, series := range s.series {
 		it.series = append(it.series, series.Iterator())
+
+		mt := int64(math.MinInt64)
+		if mtr, ok := series.(seriesMinTimer); ok {
+			mt = mtr.MinTime()
+		}
+		it.mints = append(it.mints, mt)
 	}
 	return it
 }
@@ -428,22 +992,222 @@ func (s *chainedSeries) Iterator() SeriesIterator {
 // of time-sorted, non-overlapping chunks.
 type chainedSeriesIterator struct {
 	series []SeriesIterator
+	mints  []int64 // lower time bound of the respective series, ascending
+
+	i   int
+	cur SeriesIterator
 }
 
-func (it *chainedSeriesIterator) Seek(t int64) bool {
+func (it *chainedSeriesIterator) Seek(t int64) (ok bool) {
+	if len(it.series) == 0 {
+		return false
+	}
+	// Binary search for the first series whose min time is greater than t;
+	// the series right before it is the earliest one that could still hold
+	// a sample at-or-after t, since the chain is time-sorted and
+	// non-overlapping. Fall back to that series' own Seek, advancing to
+	// later series only if it turns out to hold nothing relevant.
+	i := sort.Search(len(it.mints), func(i int) bool { return it.mints[i] > t })
+	if i > 0 {
+		i--
+	}
+	if i < it.i {
+		// Never move backwards past where we're already positioned.
+		i = it.i
+	}
+
+	for ; i < len(it.series); i++ {
+		it.i = i
+		it.cur = it.series[i]
+
+		if it.cur.Seek(t) {
+			return true
+		}
+		if err := it.cur.Err(); err != nil {
+			return false
+		}
+	}
 	return false
 }
 
 func (it *chainedSeriesIterator) Values() (t int64, v float64) {
-	return 0, 0
+	return it.cur.Values()
 }
 
 func (it *chainedSeriesIterator) Next() bool {
-	return false
+	if it.cur == nil {
+		it.cur = it.series[it.i]
+	}
+	if it.cur.Next() {
+		return true
+	}
+	if err := it.cur.Err(); err != nil {
+		return false
+	}
+	if it.i == len(it.series)-1 {
+		return false
+	}
+
+	it.i++
+	it.cur = it.series[it.i]
+
+	return it.Next()
 }
 
 func (it *chainedSeriesIterator) Err() error {
-	return nil
+	if it.cur == nil {
+		return nil
+	}
+	return it.cur.Err()
+}
+
+// dedupPolicy determines which sample mergedSeriesIterator keeps when two
+// or more of its iterators produce a value for the same timestamp.
+type dedupPolicy int
+
+const (
+	// dedupPreferFirst keeps the sample of the iterator that was passed
+	// to newMergedSeriesIterator first.
+	dedupPreferFirst dedupPolicy = iota
+	// dedupPreferLast keeps the sample of the iterator that was passed
+	// to newMergedSeriesIterator last.
+	dedupPreferLast
+	// dedupError turns a conflicting duplicate sample into an error
+	// returned from Err().
+	dedupError
+)
+
+// mergedSeriesIterator merges the data of two or more SeriesIterators that
+// may overlap in time. Samples sharing a timestamp are deduplicated
+// according to the configured dedupPolicy.
+type mergedSeriesIterator struct {
+	its   []SeriesIterator
+	dedup dedupPolicy
+
+	h       seriesIteratorHeap
+	started bool
+
+	t   int64
+	v   float64
+	err error
+}
+
+// newMergedSeriesIterator returns an iterator that merges the samples of
+// its, deduplicating samples with equal timestamps according to dedup.
+func newMergedSeriesIterator(dedup dedupPolicy, its ...SeriesIterator) *mergedSeriesIterator {
+	return &mergedSeriesIterator{its: its, dedup: dedup}
+}
+
+func (it *mergedSeriesIterator) init() {
+	it.h = make(seriesIteratorHeap, 0, len(it.its))
+
+	for _, sit := range it.its {
+		if sit.Next() {
+			it.h = append(it.h, sit)
+		} else if err := sit.Err(); err != nil {
+			it.err = err
+		}
+	}
+	heap.Init(&it.h)
+}
+
+func (it *mergedSeriesIterator) Next() bool {
+	if !it.started {
+		it.started = true
+		it.init()
+	}
+	if it.err != nil || len(it.h) == 0 {
+		return false
+	}
+
+	t, v := it.h[0].Values()
+	it.t, it.v = t, v
+
+	// The heap does not order iterators with equal timestamps, so the
+	// dedup policies compare their positions in its instead.
+	pos := func(sit SeriesIterator) int {
+		for i, s := range it.its {
+			if s == sit {
+				return i
+			}
+		}
+		return -1
+	}
+	kept := pos(it.h[0])
+
+	for first := true; len(it.h) > 0; first = false {
+		ct, cv := it.h[0].Values()
+		if ct != t {
+			break
+		}
+		if !first {
+			switch it.dedup {
+			case dedupPreferFirst:
+				if p := pos(it.h[0]); p < kept {
+					it.v, kept = cv, p
+				}
+			case dedupPreferLast:
+				if p := pos(it.h[0]); p > kept {
+					it.v, kept = cv, p
+				}
+			case dedupError:
+				if cv != it.v {
+					it.err = fmt.Errorf("conflicting samples for timestamp %d: %v and %v", t, it.v, cv)
+					return false
+				}
+			}
+		}
+
+		top := it.h[0]
+		if top.Next() {
+			heap.Fix(&it.h, 0)
+		} else {
+			if err := top.Err(); err != nil {
+				it.err = err
+				return false
+			}
+			heap.Pop(&it.h)
+		}
+	}
+	return true
+}
+
+func (it *mergedSeriesIterator) Seek(t int64) bool {
+	for it.Next() {
+		if ts, _ := it.Values(); ts >= t {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *mergedSeriesIterator) Values() (t int64, v float64) { return it.t, it.v }
+func (it *mergedSeriesIterator) Err() error                   { return it.err }
+
+// seriesIteratorHeap implements heap.Interface over a list of
+// SeriesIterators ordered by their current timestamp.
+type seriesIteratorHeap []SeriesIterator
+
+func (h seriesIteratorHeap) Len() int { return len(h) }
+
+func (h seriesIteratorHeap) Less(i, j int) bool {
+	ti, _ := h[i].Values()
+	tj, _ := h[j].Values()
+	return ti < tj
+}
+
+func (h seriesIteratorHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *seriesIteratorHeap) Push(x interface{}) {
+	*h = append(*h, x.(SeriesIterator))
+}
+
+func (h *seriesIteratorHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
 }
 
 // chunkSeriesIterator implements a series iterator on top
@@ -500,26 +1264,78 @@ func (it *chunkSeriesIterator) Err() error {
 	return it.cur.Err()
 }
 
+// bufferedSeriesIterator wraps a SeriesIterator and buffers the trailing
+// delta milliseconds of samples it emits. This lets range queries reuse a
+// single forward pass of the iterator while still being able to look back
+// at-or-before any point, which is the primitive PromQL-style rate(),
+// increase(), and deriv() need.
 type bufferedSeriesIterator struct {
-	// TODO(fabxc): time-based look back buffer for time-aggregating
-	// queries such as rate. It should allow us to re-use an iterator
-	// within a range query while calculating time-aggregates at any point.
-	//
-	// It also allows looking up/seeking at-or-before without modifying
-	// the simpler interface.
-	//
-	// Consider making this the main external interface.
 	SeriesIterator
 
-	buf []sample // lookback buffer
-	i   int      // current head
+	buf   []sample // lookback buffer, oldest first
+	delta int64
 }
 
-type sample struct {
-	t int64
-	v float64
+// NewBuffer returns an iterator that buffers the trailing delta
+// milliseconds of samples produced by it.
+func NewBuffer(it SeriesIterator, delta int64) *bufferedSeriesIterator {
+	return &bufferedSeriesIterator{SeriesIterator: it, delta: delta}
 }
 
+func (b *bufferedSeriesIterator) push(t int64, v float64) {
+	b.buf = append(b.buf, sample{t: t, v: v})
+
+	// Evict everything that fell out of the [t-delta, t] window.
+	i := 0
+	for ; i < len(b.buf); i++ {
+		if b.buf[i].t >= t-b.delta {
+			break
+		}
+	}
+	b.buf = b.buf[i:]
+}
+
+func (b *bufferedSeriesIterator) Seek(t int64) bool {
+	ok := b.SeriesIterator.Seek(t)
+	if ok {
+		ct, cv := b.SeriesIterator.Values()
+		b.push(ct, cv)
+	}
+	return ok
+}
+
+func (b *bufferedSeriesIterator) Next() bool {
+	ok := b.SeriesIterator.Next()
+	if ok {
+		t, v := b.SeriesIterator.Values()
+		b.push(t, v)
+	}
+	return ok
+}
+
+// PeekBack returns the i-th most recently emitted sample, with i=0 being
+// the one just emitted by Next/Seek, i=1 the one before that, and so on.
+// ok is false if the buffer doesn't hold that many samples.
 func (b *bufferedSeriesIterator) PeekBack(i int) (t int64, v float64, ok bool) {
+	idx := len(b.buf) - 1 - i
+	if idx < 0 {
+		return 0, 0, false
+	}
+	s := b.buf[idx]
+	return s.t, s.v, true
+}
+
+// PeekBackAt returns the newest buffered sample with a timestamp <= t.
+func (b *bufferedSeriesIterator) PeekBackAt(t int64) (rt int64, v float64, ok bool) {
+	for i := len(b.buf) - 1; i >= 0; i-- {
+		if b.buf[i].t <= t {
+			return b.buf[i].t, b.buf[i].v, true
+		}
+	}
 	return 0, 0, false
+}
+
+type sample struct {
+	t int64
+	v float64
 }
\ No newline at end of file
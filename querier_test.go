@@ -0,0 +1,98 @@
+package tsdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fakeIterator is a minimal postings Iterator backed by a fixed, sorted
+// slice of references, for exercising postings-level helpers without a
+// real IndexReader.
+type fakeIterator struct {
+	refs []uint32
+	i    int
+}
+
+func newFakeIterator(refs ...uint32) *fakeIterator {
+	return &fakeIterator{refs: refs, i: -1}
+}
+
+func (it *fakeIterator) Next() bool {
+	if it.i+1 >= len(it.refs) {
+		return false
+	}
+	it.i++
+	return true
+}
+
+func (it *fakeIterator) Seek(v uint32) bool {
+	for it.Next() {
+		if it.Value() >= v {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *fakeIterator) Value() uint32 { return it.refs[it.i] }
+func (it *fakeIterator) Err() error    { return nil }
+
+func TestMergeStrings(t *testing.T) {
+	cases := []struct {
+		a, b []string
+		exp  []string
+	}{
+		{a: nil, b: nil, exp: []string{}},
+		{a: []string{"a"}, b: nil, exp: []string{"a"}},
+		{a: nil, b: []string{"a"}, exp: []string{"a"}},
+		{a: []string{"a", "b", "c"}, b: []string{"b", "d"}, exp: []string{"a", "b", "c", "d"}},
+		{a: []string{"x"}, b: []string{"x"}, exp: []string{"x"}},
+	}
+	for _, c := range cases {
+		if got := mergeStrings(c.a, c.b); !reflect.DeepEqual(got, c.exp) {
+			t.Errorf("mergeStrings(%v, %v) = %v, want %v", c.a, c.b, got, c.exp)
+		}
+	}
+}
+
+func TestIsEmptyOnly(t *testing.T) {
+	cases := []struct {
+		vals []string
+		want bool
+	}{
+		{vals: nil, want: false},
+		{vals: []string{}, want: false},
+		{vals: []string{""}, want: true},
+		{vals: []string{"", ""}, want: true},
+		{vals: []string{"", "prod"}, want: false},
+		{vals: []string{"prod", "staging"}, want: false},
+	}
+	for _, c := range cases {
+		if got := isEmptyOnly(c.vals); got != c.want {
+			t.Errorf("isEmptyOnly(%v) = %v, want %v", c.vals, got, c.want)
+		}
+	}
+}
+
+func TestOverlapsSorted(t *testing.T) {
+	cases := []struct {
+		it   []uint32
+		set  []uint32
+		want bool
+	}{
+		{it: []uint32{1, 2, 3}, set: []uint32{4, 5}, want: false},
+		{it: []uint32{1, 2, 3}, set: []uint32{3, 4}, want: true},
+		{it: nil, set: []uint32{1}, want: false},
+		{it: []uint32{5}, set: nil, want: false},
+		{it: []uint32{1, 5, 9}, set: []uint32{2, 3, 5}, want: true},
+	}
+	for _, c := range cases {
+		ok, err := overlapsSorted(newFakeIterator(c.it...), c.set)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if ok != c.want {
+			t.Errorf("overlapsSorted(%v, %v) = %v, want %v", c.it, c.set, ok, c.want)
+		}
+	}
+}
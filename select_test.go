@@ -0,0 +1,72 @@
+package tsdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMixedSelectBranches(t *testing.T) {
+	itPlain := newFakeIterator(1)
+	itEnv := newFakeIterator(2)
+	itRegion := newFakeIterator(3)
+
+	its := []Iterator{itPlain, itEnv, itRegion}
+	absent := []string{"zone"}
+	mixed := []mixedMatcher{
+		{name: "env", itIdx: 1},
+		{name: "region", itIdx: 2},
+	}
+
+	itsBranches, absentBranches := mixedSelectBranches(its, absent, mixed)
+
+	wantIts := [][]Iterator{
+		{itPlain, itEnv, itRegion},
+		{itPlain, itRegion},
+		{itPlain, itEnv},
+		{itPlain},
+	}
+	wantAbsent := [][]string{
+		{"zone"},
+		{"zone", "env"},
+		{"zone", "region"},
+		{"zone", "env", "region"},
+	}
+
+	if len(itsBranches) != len(wantIts) {
+		t.Fatalf("got %d branches, want %d", len(itsBranches), len(wantIts))
+	}
+	for i := range wantIts {
+		if !reflect.DeepEqual(itsBranches[i], wantIts[i]) {
+			t.Errorf("branch %d its = %v, want %v", i, itsBranches[i], wantIts[i])
+		}
+		if !reflect.DeepEqual(absentBranches[i], wantAbsent[i]) {
+			t.Errorf("branch %d absent = %v, want %v", i, absentBranches[i], wantAbsent[i])
+		}
+	}
+
+	// absent must never alias across branches: mutating one must not be
+	// observable through another (regression for the shared-backing-array
+	// aliasing bug).
+	absentBranches[0] = append(absentBranches[0], "mutated")
+	if absentBranches[1][len(absentBranches[1])-1] == "mutated" {
+		t.Errorf("branch 1 absent was corrupted by appending to branch 0: %v", absentBranches[1])
+	}
+}
+
+func TestMixedSelectBranchesNoMixed(t *testing.T) {
+	itPlain := newFakeIterator(1)
+	its := []Iterator{itPlain}
+	absent := []string{"zone"}
+
+	itsBranches, absentBranches := mixedSelectBranches(its, absent, nil)
+
+	if len(itsBranches) != 1 || len(absentBranches) != 1 {
+		t.Fatalf("got %d branches, want 1", len(itsBranches))
+	}
+	if !reflect.DeepEqual(itsBranches[0], its) {
+		t.Errorf("its = %v, want %v", itsBranches[0], its)
+	}
+	if !reflect.DeepEqual(absentBranches[0], absent) {
+		t.Errorf("absent = %v, want %v", absentBranches[0], absent)
+	}
+}
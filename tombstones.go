@@ -0,0 +1,146 @@
+package tsdb
+
+import "sort"
+
+// Interval is a time range [Mint, Maxt] of samples marked for deletion.
+type Interval struct {
+	Mint, Maxt int64
+}
+
+// TombstoneReader gives read access to the intervals marked for deletion
+// for the series of a block.
+type TombstoneReader interface {
+	// Get returns the deleted intervals for the series with the given
+	// reference.
+	Get(ref uint32) ([]Interval, error)
+
+	// Seek positions the reader at ref, so that a following Get for a
+	// reference greater or equal to ref is cheap. Compaction uses it
+	// while visiting series references in increasing order to drop
+	// tombstoned samples for good when rewriting chunks.
+	Seek(ref uint32) bool
+
+	// Close releases the resources held by the reader.
+	Close() error
+}
+
+// memTombstoneReader is an in-memory TombstoneReader that also accumulates
+// newly deleted intervals.
+//
+// TODO(fabxc): persist tombstones to a per-block file instead of keeping
+// them in memory only, so deletions survive a restart before the next
+// compaction rewrites the block.
+type memTombstoneReader struct {
+	intervals map[uint32][]Interval
+
+	refs []uint32 // sorted keys of intervals, for Seek
+	i    int
+}
+
+func newMemTombstoneReader() *memTombstoneReader {
+	return &memTombstoneReader{intervals: map[uint32][]Interval{}}
+}
+
+// add records iv as deleted for the series with the given reference.
+func (r *memTombstoneReader) add(ref uint32, iv Interval) {
+	if _, ok := r.intervals[ref]; !ok {
+		i := sort.Search(len(r.refs), func(i int) bool { return r.refs[i] >= ref })
+		r.refs = append(r.refs, 0)
+		copy(r.refs[i+1:], r.refs[i:])
+		r.refs[i] = ref
+	}
+	r.intervals[ref] = append(r.intervals[ref], iv)
+}
+
+func (r *memTombstoneReader) Get(ref uint32) ([]Interval, error) {
+	return r.intervals[ref], nil
+}
+
+func (r *memTombstoneReader) Seek(ref uint32) bool {
+	r.i = sort.Search(len(r.refs), func(i int) bool { return r.refs[i] >= ref })
+	return r.i < len(r.refs) && r.refs[r.i] == ref
+}
+
+func (r *memTombstoneReader) Close() error { return nil }
+
+// deletedSeriesIterator wraps a SeriesIterator and skips over any sample
+// whose timestamp falls inside one of dranges.
+type deletedSeriesIterator struct {
+	it      SeriesIterator
+	dranges []Interval
+}
+
+func (it *deletedSeriesIterator) isDeleted(t int64) bool {
+	for _, iv := range it.dranges {
+		if t >= iv.Mint && t <= iv.Maxt {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *deletedSeriesIterator) Next() bool {
+	for it.it.Next() {
+		if t, _ := it.it.Values(); !it.isDeleted(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *deletedSeriesIterator) Seek(t int64) bool {
+	if !it.it.Seek(t) {
+		return false
+	}
+	if ts, _ := it.it.Values(); !it.isDeleted(ts) {
+		return true
+	}
+	return it.Next()
+}
+
+func (it *deletedSeriesIterator) Values() (t int64, v float64) { return it.it.Values() }
+func (it *deletedSeriesIterator) Err() error                   { return it.it.Err() }
+
+// Delete marks all samples in [mint, maxt] for the series matching ms for
+// deletion across every shard. They are filtered out of query results
+// immediately and dropped for good the next time their block is
+// compacted.
+func (db *DB) Delete(mint, maxt int64, ms ...Matcher) error {
+	for _, s := range db.shards {
+		if err := s.Delete(mint, maxt, ms...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete marks all samples in [mint, maxt] for the series matching ms for
+// deletion in every block of the shard that overlaps the range.
+func (s *SeriesShard) Delete(mint, maxt int64, ms ...Matcher) error {
+	for _, b := range s.blocksForRange(mint, maxt) {
+		if err := b.Delete(mint, maxt, ms...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete marks all samples in [mint, maxt] for the series matching ms for
+// deletion in this block.
+func (pb *Block) Delete(mint, maxt int64, ms ...Matcher) error {
+	// Resolve matches the same way Select does, so a matcher like
+	// MatchEquals(name, "") deletes data for series missing that label
+	// instead of silently matching nothing.
+	it := newBlockQuerier(pb.index, pb.chunks, pb.tombstones, mint, maxt).selectRefs(ms...)
+
+	for it.Next() {
+		pb.tombstones.add(it.Value(), Interval{Mint: mint, Maxt: maxt})
+	}
+	return it.Err()
+}
+
+// Tombstones returns the reader for the intervals marked for deletion in
+// the block.
+func (pb *Block) Tombstones() TombstoneReader {
+	return pb.tombstones
+}
@@ -0,0 +1,73 @@
+package tsdb
+
+import "testing"
+
+func TestMemTombstoneReader(t *testing.T) {
+	r := newMemTombstoneReader()
+	r.add(1, Interval{Mint: 10, Maxt: 20})
+	r.add(1, Interval{Mint: 40, Maxt: 50})
+	r.add(3, Interval{Mint: 5, Maxt: 5})
+
+	ivs, err := r.Get(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []Interval{{Mint: 10, Maxt: 20}, {Mint: 40, Maxt: 50}}
+	if len(ivs) != len(want) || ivs[0] != want[0] || ivs[1] != want[1] {
+		t.Errorf("Get(1) = %v, want %v", ivs, want)
+	}
+
+	if ivs, _ := r.Get(2); len(ivs) != 0 {
+		t.Errorf("Get(2) = %v, want none", ivs)
+	}
+
+	if !r.Seek(3) {
+		t.Errorf("Seek(3) = false, want true")
+	}
+	if r.Seek(2) {
+		t.Errorf("Seek(2) = true, want false")
+	}
+}
+
+func TestDeletedSeriesIterator(t *testing.T) {
+	it := &deletedSeriesIterator{
+		it: newSampleSeriesIterator(
+			sample{0, 0}, sample{10, 1}, sample{20, 2}, sample{30, 3}, sample{40, 4},
+		),
+		dranges: []Interval{{Mint: 10, Maxt: 20}},
+	}
+
+	var got []sample
+	for it.Next() {
+		ts, v := it.Values()
+		got = append(got, sample{ts, v})
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []sample{{0, 0}, {30, 3}, {40, 4}}
+	if len(got) != len(want) {
+		t.Fatalf("Next() produced %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Next() produced %v, want %v", got, want)
+		}
+	}
+}
+
+func TestDeletedSeriesIteratorSeek(t *testing.T) {
+	it := &deletedSeriesIterator{
+		it: newSampleSeriesIterator(
+			sample{0, 0}, sample{10, 1}, sample{20, 2}, sample{30, 3},
+		),
+		dranges: []Interval{{Mint: 10, Maxt: 20}},
+	}
+
+	if !it.Seek(10) {
+		t.Fatalf("Seek(10) = false, want true")
+	}
+	if ts, v := it.Values(); ts != 30 || v != 3 {
+		t.Errorf("Seek(10) landed on (%d, %v), want (30, 3)", ts, v)
+	}
+}